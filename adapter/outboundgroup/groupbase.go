@@ -3,7 +3,6 @@ package outboundgroup
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"sync"
 	"time"
 
@@ -19,15 +18,24 @@ import (
 
 type GroupBase struct {
 	*outbound.Base
-	filter        *regexp2.Regexp
-	weightFilter  string
-	providers     []provider.ProxyProvider
-	failedTestMux sync.Mutex
-	failedTimes   int
-	failedTime    time.Time
-	failedTesting *atomic.Bool
-	proxies       [][]C.Proxy
-	versions      []atomic.Uint32
+	filter         *regexp2.Regexp
+	weightFilter   string
+	weightFilterFn weightPredicate
+	providers      []provider.ProxyProvider
+	failedTestMux  sync.Mutex
+	failedTimes    int
+	failedTime     time.Time
+	failedTesting  *atomic.Bool
+
+	maxFailedThreshold int
+	failedTimeout      time.Duration
+
+	healthCheckCooldown  time.Duration
+	breakerBackoff       time.Duration // guarded by failedTestMux
+	breakerCooldownUntil *atomic.Int64 // unix nanoseconds; checked lock-free
+	healthCheckEvents    chan HealthCheckEvent
+
+	proxyCache groupProxyCache
 }
 
 type GroupBaseOption struct {
@@ -35,135 +43,135 @@ type GroupBaseOption struct {
 	filter       string
 	weightFilter string
 	providers    []provider.ProxyProvider
+
+	// maxFailedTimes, failedTimeoutInterval and healthCheckCooldown tune the
+	// group's active health-check policy. Zero means "use the default".
+	maxFailedTimes        int
+	failedTimeoutInterval time.Duration
+	healthCheckCooldown   time.Duration
 }
 
-func NewGroupBase(opt GroupBaseOption) *GroupBase {
+func NewGroupBase(opt GroupBaseOption) (*GroupBase, error) {
 	var filter *regexp2.Regexp = nil
 	if opt.filter != "" {
 		filter = regexp2.MustCompile(opt.filter, 0)
 	}
 
-	gb := &GroupBase{
-		Base:          outbound.NewBase(opt.BaseOption),
-		filter:        filter,
-		weightFilter:  opt.weightFilter,
-		providers:     opt.providers,
-		failedTesting: atomic.NewBool(false),
+	weightFilterFn, err := compileWeightFilter(opt.weightFilter)
+	if err != nil {
+		return nil, err
 	}
 
-	gb.proxies = make([][]C.Proxy, len(opt.providers))
-	gb.versions = make([]atomic.Uint32, len(opt.providers))
+	maxFailedThreshold := opt.maxFailedTimes
+	if maxFailedThreshold <= 0 {
+		maxFailedThreshold = defaultMaxFailedTimes
+	}
 
-	return gb
-}
+	failedTimeout := opt.failedTimeoutInterval
+	if failedTimeout <= 0 {
+		failedTimeout = defaultFailedTimeoutInterval
+	}
 
-func filterProxyByWeight(proxies []C.Proxy, weightFilter string) []C.Proxy {
-	if weightFilter == "" {
-		return proxies
-	}
-	var newProxies []C.Proxy
-	re := regexp2.MustCompile(`(==|<=|!=|>=)([\d]+)`, 0)
-	if m, _ := re.FindStringMatch(weightFilter); m != nil {
-		gps := m.Groups()
-		opt := gps[1].Captures[0].String()
-		val, err := strconv.Atoi(gps[2].Captures[0].String())
-		if err != nil {
-			panic("invalid weight filter")
-		}
+	healthCheckCooldown := opt.healthCheckCooldown
+	if healthCheckCooldown <= 0 {
+		healthCheckCooldown = defaultHealthCheckCooldown
+	}
 
-		for _, p := range proxies {
-			switch opt {
-			case "==":
-				if p.Weight() == val {
-					newProxies = append(newProxies, p)
-				}
-			case "!=":
-				if p.Weight() != val {
-					newProxies = append(newProxies, p)
-				}
-			case ">=":
-				if p.Weight() >= val {
-					newProxies = append(newProxies, p)
-				}
-			case "<=":
-				if p.Weight() <= val {
-					newProxies = append(newProxies, p)
-				}
-			default:
-				panic("invalid weight filter")
-			}
-		}
-	} else {
-		newProxies = append(newProxies, proxies...)
+	gb := &GroupBase{
+		Base:                 outbound.NewBase(opt.BaseOption),
+		filter:               filter,
+		weightFilter:         opt.weightFilter,
+		weightFilterFn:       weightFilterFn,
+		providers:            opt.providers,
+		failedTesting:        atomic.NewBool(false),
+		maxFailedThreshold:   maxFailedThreshold,
+		failedTimeout:        failedTimeout,
+		healthCheckCooldown:  healthCheckCooldown,
+		breakerCooldownUntil: atomic.NewInt64(0),
+		healthCheckEvents:    make(chan HealthCheckEvent, 16),
 	}
 
-	return newProxies
+	return gb, nil
+}
+
+// proxiesWithTouch snapshots a provider's proxies, optionally notifying its
+// LRU of the access first, so the two never drift apart at call sites.
+//
+// TODO: this is a package-local helper rather than a method on
+// provider.ProxyProvider because that interface, and every type
+// implementing it, lives outside adapter/outboundgroup and is out of scope
+// for this change. Any other package still doing the separate
+// Touch()/Proxies() sequence is exactly as divergence-prone as before this
+// change. Whoever owns constant/provider should promote this to a real
+// ProxiesWithTouch(touch bool) method on ProxyProvider so every caller
+// shares one atomic implementation.
+func proxiesWithTouch(pd provider.ProxyProvider, touch bool) []C.Proxy {
+	if touch {
+		pd.Touch()
+	}
+	return pd.Proxies()
 }
 
 func (gb *GroupBase) GetProxies(touch bool) []C.Proxy {
 	if gb.filter == nil {
 		var proxies []C.Proxy
 		for _, pd := range gb.providers {
-			if touch {
-				pd.Touch()
-			}
-
-			for _, p := range pd.Proxies() {
-				proxies = append(proxies, p)
-			}
+			proxies = append(proxies, proxiesWithTouch(pd, touch)...)
 		}
 		if len(proxies) == 0 {
 			return append(proxies, tunnel.Proxies()["COMPATIBLE"])
 		}
-		return filterProxyByWeight(proxies, gb.weightFilter)
+		return filterProxies(proxies, gb.weightFilterFn)
 	}
 
-	for i, pd := range gb.providers {
-		if touch {
-			pd.Touch()
-		}
+	live := make(map[string]struct{}, len(gb.providers))
+	for _, pd := range gb.providers {
+		pd := pd
+		name := pd.Name()
+		live[name] = struct{}{}
 
 		if pd.VehicleType() == types.Compatible {
-			gb.versions[i].Store(pd.Version())
-			gb.proxies[i] = pd.Proxies()
+			gb.proxyCache.set(name, &proxyCacheEntry{
+				version: pd.Version(),
+				proxies: proxiesWithTouch(pd, touch),
+			})
 			continue
 		}
 
-		version := gb.versions[i].Load()
-		if version != pd.Version() && gb.versions[i].CAS(version, pd.Version()) {
-			var (
-				proxies    []C.Proxy
-				newProxies []C.Proxy
-			)
-
-			proxies = pd.Proxies()
-
-			//
-			if gb.filter != nil {
-				for _, p := range proxies {
-					if mat, _ := gb.filter.FindStringMatch(p.Name()); mat != nil {
-						newProxies = append(newProxies, p)
-					}
-				}
-			} else {
-				for _, p := range proxies {
+		version := pd.Version()
+		if cached, ok := gb.proxyCache.get(name); ok && cached.version == version {
+			if touch {
+				pd.Touch()
+			}
+			continue
+		}
+
+		gb.proxyCache.refresh(name, version, func() []C.Proxy {
+			var newProxies []C.Proxy
+			for _, p := range proxiesWithTouch(pd, touch) {
+				if mat, _ := gb.filter.FindStringMatch(p.Name()); mat != nil {
 					newProxies = append(newProxies, p)
 				}
 			}
-
-			gb.proxies[i] = newProxies
-		}
+			return newProxies
+		})
 	}
 
+	// Drop cache entries for providers that no longer belong to the group,
+	// e.g. after a hot-reload replaced gb.providers.
+	gb.proxyCache.prune(live)
+
 	var proxies []C.Proxy
-	for _, p := range gb.proxies {
-		proxies = append(proxies, p...)
+	for _, pd := range gb.providers {
+		if cached, ok := gb.proxyCache.get(pd.Name()); ok {
+			proxies = append(proxies, cached.proxies...)
+		}
 	}
 
 	if len(proxies) == 0 {
 		return append(proxies, tunnel.Proxies()["COMPATIBLE"])
 	}
-	return filterProxyByWeight(proxies, gb.weightFilter)
+	return filterProxies(proxies, gb.weightFilterFn)
 }
 
 func (gb *GroupBase) URLTest(ctx context.Context, url string) (map[string]uint16, error) {
@@ -198,11 +206,18 @@ func (gb *GroupBase) onDialFailed() {
 	if gb.failedTesting.Load() {
 		return
 	}
+	if gb.inCooldown() {
+		return
+	}
 
 	go func() {
 		gb.failedTestMux.Lock()
 		defer gb.failedTestMux.Unlock()
 
+		if gb.inCooldown() {
+			return
+		}
+
 		gb.failedTimes++
 		if gb.failedTimes == 1 {
 			log.Debugln("ProxyGroup: %s first failed", gb.Name())
@@ -215,8 +230,7 @@ func (gb *GroupBase) onDialFailed() {
 
 			log.Debugln("ProxyGroup: %s failed count: %d", gb.Name(), gb.failedTimes)
 			if gb.failedTimes >= gb.maxFailedTimes() {
-				gb.failedTesting.Store(true)
-				log.Warnln("because %s failed multiple times, active health check", gb.Name())
+				gb.openBreaker()
 				wg := sync.WaitGroup{}
 				for _, proxyProvider := range gb.providers {
 					wg.Add(1)
@@ -228,27 +242,54 @@ func (gb *GroupBase) onDialFailed() {
 				}
 
 				wg.Wait()
-				gb.failedTesting.Store(false)
+				gb.closeBreaker()
 				gb.failedTimes = 0
 			}
 		}
 	}()
 }
 
+func (gb *GroupBase) openBreaker() {
+	gb.failedTesting.Store(true)
+	log.Warnln("ProxyGroup: %s failed multiple times, starting active health check", gb.Name())
+	gb.emitHealthCheckEvent(HealthCheckBreakerOpened)
+}
+
+// closeBreaker must be called with failedTestMux held, since it reads and
+// writes breakerBackoff.
+func (gb *GroupBase) closeBreaker() {
+	gb.failedTesting.Store(false)
+	cooldownUntil := time.Now().Add(gb.healthCheckCooldown + gb.nextBreakerBackoff())
+	gb.breakerCooldownUntil.Store(cooldownUntil.UnixNano())
+	log.Infoln("ProxyGroup: %s active health check finished, next one allowed after %s", gb.Name(), time.Until(cooldownUntil).Round(time.Second))
+	gb.emitHealthCheckEvent(HealthCheckBreakerClosed)
+}
+
+// inCooldown reports whether the group's health-check breaker is still in
+// its post-probe cooldown window. It's lock-free so onDialFailed's hot path
+// can bail before spawning a goroutine or touching failedTestMux.
+func (gb *GroupBase) inCooldown() bool {
+	return time.Now().UnixNano() < gb.breakerCooldownUntil.Load()
+}
+
 func (gb *GroupBase) failedIntervalTime() int64 {
-	return 5 * time.Second.Milliseconds()
+	return gb.failedTimeout.Milliseconds()
 }
 
 func (gb *GroupBase) onDialSuccess() {
+	gb.failedTestMux.Lock()
+	defer gb.failedTestMux.Unlock()
+
 	if !gb.failedTesting.Load() {
 		gb.failedTimes = 0
+		gb.breakerBackoff = 0
 	}
 }
 
 func (gb *GroupBase) maxFailedTimes() int {
-	return 5
+	return gb.maxFailedThreshold
 }
 
 func (gb *GroupBase) failedTimeoutInterval() time.Duration {
-	return 5 * time.Second
+	return gb.failedTimeout
 }
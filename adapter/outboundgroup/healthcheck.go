@@ -0,0 +1,68 @@
+package outboundgroup
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultMaxFailedTimes        = 5
+	defaultFailedTimeoutInterval = 5 * time.Second
+	defaultHealthCheckCooldown   = 30 * time.Second
+	maxHealthCheckBackoff        = 2 * time.Minute
+)
+
+// HealthCheckEventType distinguishes the two transitions a group's active
+// health-check breaker can report.
+type HealthCheckEventType int
+
+const (
+	// HealthCheckBreakerOpened is emitted right before a group starts an
+	// active probe across all of its providers.
+	HealthCheckBreakerOpened HealthCheckEventType = iota
+	// HealthCheckBreakerClosed is emitted once that probe has finished and
+	// the group has entered its cooldown window.
+	HealthCheckBreakerClosed
+)
+
+// HealthCheckEvent lets the API/UI layer observe a group's active
+// health-check breaker without tailing debug logs.
+type HealthCheckEvent struct {
+	GroupName string
+	Type      HealthCheckEventType
+	Time      time.Time
+}
+
+// HealthCheckEvents returns a receive-only channel of breaker open/close
+// events for this group. Sends are non-blocking, so a slow or absent
+// consumer never stalls health checking.
+func (gb *GroupBase) HealthCheckEvents() <-chan HealthCheckEvent {
+	return gb.healthCheckEvents
+}
+
+func (gb *GroupBase) emitHealthCheckEvent(typ HealthCheckEventType) {
+	select {
+	case gb.healthCheckEvents <- HealthCheckEvent{GroupName: gb.Name(), Type: typ, Time: time.Now()}:
+	default:
+	}
+}
+
+// nextBreakerBackoff doubles the previous backoff (starting from the group's
+// failedTimeoutInterval) each time the breaker opens, capped at
+// maxHealthCheckBackoff, and applies up to 50% jitter so repeated failures
+// don't retrigger probes in lockstep.
+func (gb *GroupBase) nextBreakerBackoff() time.Duration {
+	backoff := gb.breakerBackoff
+	if backoff == 0 {
+		backoff = gb.failedTimeoutInterval()
+	} else {
+		backoff *= 2
+	}
+	if backoff > maxHealthCheckBackoff {
+		backoff = maxHealthCheckBackoff
+	}
+	gb.breakerBackoff = backoff
+
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff/2 + jitter
+}
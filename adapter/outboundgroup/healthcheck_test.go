@@ -0,0 +1,110 @@
+package outboundgroup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dreamacro/clash/adapter/outbound"
+)
+
+func newTestGroupBase(t *testing.T, failedTimeout time.Duration) *GroupBase {
+	t.Helper()
+	gb, err := NewGroupBase(GroupBaseOption{
+		BaseOption:            outbound.BaseOption{Name: "test"},
+		failedTimeoutInterval: failedTimeout,
+	})
+	if err != nil {
+		t.Fatalf("NewGroupBase returned error: %v", err)
+	}
+	return gb
+}
+
+func TestNextBreakerBackoffDoublesAndCaps(t *testing.T) {
+	gb := newTestGroupBase(t, time.Second)
+
+	gb.nextBreakerBackoff()
+	if gb.breakerBackoff != time.Second {
+		t.Fatalf("first backoff = %s, want %s", gb.breakerBackoff, time.Second)
+	}
+
+	gb.nextBreakerBackoff()
+	if gb.breakerBackoff != 2*time.Second {
+		t.Fatalf("second backoff = %s, want %s", gb.breakerBackoff, 2*time.Second)
+	}
+
+	// Keep doubling well past maxHealthCheckBackoff and confirm it never
+	// exceeds the cap once it gets there.
+	for i := 0; i < 20; i++ {
+		gb.nextBreakerBackoff()
+		if gb.breakerBackoff > maxHealthCheckBackoff {
+			t.Fatalf("backoff = %s exceeds cap %s", gb.breakerBackoff, maxHealthCheckBackoff)
+		}
+	}
+	if gb.breakerBackoff != maxHealthCheckBackoff {
+		t.Fatalf("backoff = %s, want it to have settled at the cap %s", gb.breakerBackoff, maxHealthCheckBackoff)
+	}
+}
+
+func TestNextBreakerBackoffJitterInBounds(t *testing.T) {
+	gb := newTestGroupBase(t, time.Second)
+
+	for i := 0; i < 50; i++ {
+		wait := gb.nextBreakerBackoff()
+		full := gb.breakerBackoff
+
+		if wait < full/2 || wait > full {
+			t.Fatalf("nextBreakerBackoff() = %s, want it within [%s, %s]", wait, full/2, full)
+		}
+	}
+}
+
+func TestInCooldownGatesOnDialFailed(t *testing.T) {
+	gb := newTestGroupBase(t, time.Second)
+	gb.breakerCooldownUntil.Store(time.Now().Add(time.Minute).UnixNano())
+
+	if !gb.inCooldown() {
+		t.Fatalf("expected gb to report being in cooldown")
+	}
+
+	gb.onDialFailed()
+
+	if gb.failedTimes != 0 {
+		t.Fatalf("onDialFailed() incremented failedTimes to %d during cooldown, want it to bail before touching state", gb.failedTimes)
+	}
+}
+
+func TestHealthCheckEventSequence(t *testing.T) {
+	gb := newTestGroupBase(t, time.Second)
+
+	gb.openBreaker()
+	gb.failedTestMux.Lock()
+	gb.closeBreaker() // closeBreaker requires failedTestMux held, see its doc comment
+	gb.failedTestMux.Unlock()
+
+	events := gb.HealthCheckEvents()
+
+	select {
+	case ev := <-events:
+		if ev.Type != HealthCheckBreakerOpened {
+			t.Fatalf("first event type = %v, want HealthCheckBreakerOpened", ev.Type)
+		}
+		if ev.GroupName != gb.Name() {
+			t.Fatalf("first event GroupName = %q, want %q", ev.GroupName, gb.Name())
+		}
+	default:
+		t.Fatalf("expected an event after openBreaker()")
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != HealthCheckBreakerClosed {
+			t.Fatalf("second event type = %v, want HealthCheckBreakerClosed", ev.Type)
+		}
+	default:
+		t.Fatalf("expected an event after closeBreaker()")
+	}
+
+	if !gb.inCooldown() {
+		t.Fatalf("expected closeBreaker() to leave the group in its cooldown window")
+	}
+}
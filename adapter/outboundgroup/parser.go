@@ -0,0 +1,51 @@
+package outboundgroup
+
+import (
+	"time"
+
+	"github.com/Dreamacro/clash/adapter/outbound"
+	"github.com/Dreamacro/clash/common/structure"
+	"github.com/Dreamacro/clash/constant/provider"
+)
+
+// GroupCommonOption holds the YAML fields shared by every proxy-group type.
+// It decodes with the same structure.Decoder convention adapter.ParseProxy
+// uses for individual proxies, just keyed by "group" instead of "proxy".
+type GroupCommonOption struct {
+	Name         string `group:"name"`
+	Filter       string `group:"filter,omitempty"`
+	WeightFilter string `group:"weight-filter,omitempty"`
+
+	// MaxFailedTimes, FailedTimeout and HealthCheckCooldown tune the
+	// group's active health-check policy. MaxFailedTimes is a count of
+	// failed dials; FailedTimeout and HealthCheckCooldown are in seconds.
+	// Zero means "use the default" (see the defaults in healthcheck.go).
+	MaxFailedTimes      int `group:"max-failed-times,omitempty"`
+	FailedTimeout       int `group:"failed-timeout,omitempty"`
+	HealthCheckCooldown int `group:"health-check-cooldown,omitempty"`
+}
+
+// ParseGroupCommonOption decodes the YAML fields shared by every proxy-group
+// type out of a group's raw config mapping.
+func ParseGroupCommonOption(config map[string]any) (*GroupCommonOption, error) {
+	decoder := structure.NewDecoder(structure.Option{TagName: "group", WeaklyTypedInput: true, KeyReplacer: structure.DefaultKeyReplacer})
+	option := &GroupCommonOption{}
+	if err := decoder.Decode(config, option); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+// BaseOption builds a GroupBaseOption from the decoded YAML fields and the
+// providers already resolved for this group.
+func (option GroupCommonOption) BaseOption(providers []provider.ProxyProvider) GroupBaseOption {
+	return GroupBaseOption{
+		BaseOption:            outbound.BaseOption{Name: option.Name},
+		filter:                option.Filter,
+		weightFilter:          option.WeightFilter,
+		providers:             providers,
+		maxFailedTimes:        option.MaxFailedTimes,
+		failedTimeoutInterval: time.Duration(option.FailedTimeout) * time.Second,
+		healthCheckCooldown:   time.Duration(option.HealthCheckCooldown) * time.Second,
+	}
+}
@@ -0,0 +1,54 @@
+package outboundgroup
+
+import "testing"
+
+func TestParseGroupCommonOptionWiresHealthCheckPolicy(t *testing.T) {
+	config := map[string]any{
+		"name":                  "auto",
+		"max-failed-times":      3,
+		"failed-timeout":        10,
+		"health-check-cooldown": 60,
+	}
+
+	option, err := ParseGroupCommonOption(config)
+	if err != nil {
+		t.Fatalf("ParseGroupCommonOption returned error: %v", err)
+	}
+
+	gb, err := NewGroupBase(option.BaseOption(nil))
+	if err != nil {
+		t.Fatalf("NewGroupBase returned error: %v", err)
+	}
+
+	if got := gb.maxFailedTimes(); got != 3 {
+		t.Fatalf("maxFailedTimes() = %d, want 3", got)
+	}
+	if got := gb.failedTimeoutInterval(); got.Seconds() != 10 {
+		t.Fatalf("failedTimeoutInterval() = %s, want 10s", got)
+	}
+	if got := gb.healthCheckCooldown; got.Seconds() != 60 {
+		t.Fatalf("healthCheckCooldown = %s, want 60s", got)
+	}
+}
+
+func TestParseGroupCommonOptionDefaultsWhenUnset(t *testing.T) {
+	option, err := ParseGroupCommonOption(map[string]any{"name": "auto"})
+	if err != nil {
+		t.Fatalf("ParseGroupCommonOption returned error: %v", err)
+	}
+
+	gb, err := NewGroupBase(option.BaseOption(nil))
+	if err != nil {
+		t.Fatalf("NewGroupBase returned error: %v", err)
+	}
+
+	if got := gb.maxFailedTimes(); got != defaultMaxFailedTimes {
+		t.Fatalf("maxFailedTimes() = %d, want default %d", got, defaultMaxFailedTimes)
+	}
+	if got := gb.failedTimeoutInterval(); got != defaultFailedTimeoutInterval {
+		t.Fatalf("failedTimeoutInterval() = %s, want default %s", got, defaultFailedTimeoutInterval)
+	}
+	if got := gb.healthCheckCooldown; got != defaultHealthCheckCooldown {
+		t.Fatalf("healthCheckCooldown = %s, want default %s", got, defaultHealthCheckCooldown)
+	}
+}
@@ -0,0 +1,65 @@
+package outboundgroup
+
+import (
+	"sync"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// proxyCacheEntry holds the last filtered snapshot taken from a provider,
+// keyed by provider name so it stays valid across reorders/hot-reloads of
+// gb.providers.
+type proxyCacheEntry struct {
+	version uint32
+	proxies []C.Proxy
+}
+
+// groupProxyCache caches each provider's filtered proxy snapshot keyed by
+// provider name. refresh serializes recomputation per name, so concurrent
+// callers that all observe the same stale version don't each re-fetch and
+// re-filter the provider's proxies at once.
+type groupProxyCache struct {
+	entries sync.Map // map[string]*proxyCacheEntry
+	locks   sync.Map // map[string]*sync.Mutex
+}
+
+func (c *groupProxyCache) get(name string) (*proxyCacheEntry, bool) {
+	v, ok := c.entries.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return v.(*proxyCacheEntry), true
+}
+
+func (c *groupProxyCache) set(name string, entry *proxyCacheEntry) {
+	c.entries.Store(name, entry)
+}
+
+// refresh recomputes and stores the entry for name unless another caller
+// already brought it up to date with version while this one was waiting.
+func (c *groupProxyCache) refresh(name string, version uint32, compute func() []C.Proxy) *proxyCacheEntry {
+	muAny, _ := c.locks.LoadOrStore(name, &sync.Mutex{})
+	mu := muAny.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cached, ok := c.get(name); ok && cached.version == version {
+		return cached
+	}
+
+	entry := &proxyCacheEntry{version: version, proxies: compute()}
+	c.set(name, entry)
+	return entry
+}
+
+// prune drops cache entries (and their locks) for providers no longer
+// present in live, e.g. after a hot-reload replaced the group's providers.
+func (c *groupProxyCache) prune(live map[string]struct{}) {
+	c.entries.Range(func(key, _ any) bool {
+		if _, ok := live[key.(string)]; !ok {
+			c.entries.Delete(key)
+			c.locks.Delete(key)
+		}
+		return true
+	})
+}
@@ -0,0 +1,95 @@
+package outboundgroup
+
+import (
+	"testing"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+func TestGroupProxyCachePruneRemovesStaleProviders(t *testing.T) {
+	var cache groupProxyCache
+	cache.set("p1", &proxyCacheEntry{version: 1})
+	cache.set("p2", &proxyCacheEntry{version: 1})
+	cache.set("p3", &proxyCacheEntry{version: 1})
+
+	// Simulate a provider reorder/removal: only p1 and p3 are still part of
+	// the group.
+	cache.prune(map[string]struct{}{"p1": {}, "p3": {}})
+
+	if _, ok := cache.get("p1"); !ok {
+		t.Fatalf("expected p1 to survive prune")
+	}
+	if _, ok := cache.get("p3"); !ok {
+		t.Fatalf("expected p3 to survive prune")
+	}
+	if _, ok := cache.get("p2"); ok {
+		t.Fatalf("expected p2 to be pruned once it left the group's provider list")
+	}
+}
+
+func TestGroupProxyCacheRefreshSkipsRecomputeWhenUpToDate(t *testing.T) {
+	var cache groupProxyCache
+	cache.set("p1", &proxyCacheEntry{version: 1, proxies: []C.Proxy{nil}})
+
+	called := false
+	entry := cache.refresh("p1", 1, func() []C.Proxy {
+		called = true
+		return nil
+	})
+
+	if called {
+		t.Fatalf("refresh recomputed even though the cached version was already current")
+	}
+	if len(entry.proxies) != 1 {
+		t.Fatalf("expected the untouched cached entry to be returned")
+	}
+}
+
+func TestGroupProxyCacheRefreshRecomputesOnStaleVersion(t *testing.T) {
+	var cache groupProxyCache
+	cache.set("p1", &proxyCacheEntry{version: 1})
+
+	want := []C.Proxy{nil, nil}
+	entry := cache.refresh("p1", 2, func() []C.Proxy { return want })
+
+	if entry.version != 2 {
+		t.Fatalf("expected the refreshed entry to carry the new version, got %d", entry.version)
+	}
+	if len(entry.proxies) != len(want) {
+		t.Fatalf("expected the refreshed entry's proxies to come from compute()")
+	}
+	if cached, ok := cache.get("p1"); !ok || cached.version != 2 {
+		t.Fatalf("expected refresh to store the recomputed entry")
+	}
+}
+
+// TestGroupProxyCacheRefreshSerializesConcurrentMisses exercises the
+// per-name lock that guards against the stampede the original index-keyed
+// cache was prone to: many goroutines observing the same stale version for
+// the same provider must only recompute once.
+func TestGroupProxyCacheRefreshSerializesConcurrentMisses(t *testing.T) {
+	var cache groupProxyCache
+	cache.set("p1", &proxyCacheEntry{version: 1})
+
+	const goroutines = 32
+	done := make(chan int)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			computed := 0
+			cache.refresh("p1", 2, func() []C.Proxy {
+				computed++
+				return []C.Proxy{nil}
+			})
+			done <- computed
+		}()
+	}
+
+	total := 0
+	for i := 0; i < goroutines; i++ {
+		total += <-done
+	}
+
+	if total != 1 {
+		t.Fatalf("expected exactly one goroutine to recompute the stale entry, got %d", total)
+	}
+}
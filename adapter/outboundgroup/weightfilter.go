@@ -0,0 +1,399 @@
+package outboundgroup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/dlclark/regexp2"
+)
+
+// weightFilterTarget is the slice of C.Proxy's method set that the weight
+// filter expression language actually needs. Keeping it narrow lets tests
+// exercise compileWeightFilter without a full C.Proxy fake; every C.Proxy
+// already satisfies it.
+type weightFilterTarget interface {
+	Name() string
+	Weight() int
+}
+
+// weightPredicate is compiled once per group from the group's weightFilter
+// expression and reused on every call to GetProxies.
+type weightPredicate func(weightFilterTarget) bool
+
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokNumber
+	tokComparator
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokIdent
+	tokMatch // =~
+	tokString
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+// filterLexer tokenizes a weightFilter expression such as
+// `>=50 && <=100` or `weight>=50 && name=~"US"`.
+type filterLexer struct {
+	src []rune
+	pos int
+}
+
+func newFilterLexer(src string) *filterLexer {
+	return &filterLexer{src: []rune(src)}
+}
+
+func (l *filterLexer) peek() (rune, bool) {
+	if l.pos >= len(l.src) {
+		return 0, false
+	}
+	return l.src[l.pos], true
+}
+
+func (l *filterLexer) next() (filterToken, error) {
+	for {
+		r, ok := l.peek()
+		if !ok {
+			return filterToken{kind: tokEOF}, nil
+		}
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos++
+	}
+
+	r, _ := l.peek()
+	switch {
+	case r == '(':
+		l.pos++
+		return filterToken{kind: tokLParen, text: "("}, nil
+	case r == ')':
+		l.pos++
+		return filterToken{kind: tokRParen, text: ")"}, nil
+	case r == '!':
+		l.pos++
+		if nr, ok := l.peek(); ok && nr == '=' {
+			l.pos++
+			return filterToken{kind: tokComparator, text: "!="}, nil
+		}
+		return filterToken{kind: tokNot, text: "!"}, nil
+	case r == '=':
+		l.pos++
+		if nr, ok := l.peek(); ok && nr == '=' {
+			l.pos++
+			return filterToken{kind: tokComparator, text: "=="}, nil
+		}
+		if nr, ok := l.peek(); ok && nr == '~' {
+			l.pos++
+			return filterToken{kind: tokMatch, text: "=~"}, nil
+		}
+		return filterToken{}, fmt.Errorf("unexpected '=' at offset %d", l.pos-1)
+	case r == '>':
+		l.pos++
+		if nr, ok := l.peek(); ok && nr == '=' {
+			l.pos++
+			return filterToken{kind: tokComparator, text: ">="}, nil
+		}
+		return filterToken{kind: tokComparator, text: ">"}, nil
+	case r == '<':
+		l.pos++
+		if nr, ok := l.peek(); ok && nr == '=' {
+			l.pos++
+			return filterToken{kind: tokComparator, text: "<="}, nil
+		}
+		return filterToken{kind: tokComparator, text: "<"}, nil
+	case r == '&':
+		l.pos++
+		if nr, ok := l.peek(); ok && nr == '&' {
+			l.pos++
+			return filterToken{kind: tokAnd, text: "&&"}, nil
+		}
+		return filterToken{}, fmt.Errorf("unexpected '&' at offset %d", l.pos-1)
+	case r == '|':
+		l.pos++
+		if nr, ok := l.peek(); ok && nr == '|' {
+			l.pos++
+			return filterToken{kind: tokOr, text: "||"}, nil
+		}
+		return filterToken{}, fmt.Errorf("unexpected '|' at offset %d", l.pos-1)
+	case r == '"' || r == '/':
+		return l.lexString(r)
+	case unicode.IsDigit(r):
+		return l.lexNumber(), nil
+	case unicode.IsLetter(r):
+		return l.lexIdent(), nil
+	default:
+		return filterToken{}, fmt.Errorf("unexpected character %q at offset %d", r, l.pos)
+	}
+}
+
+func (l *filterLexer) lexString(delim rune) (filterToken, error) {
+	l.pos++ // consume opening delimiter
+	start := l.pos
+	for {
+		r, ok := l.peek()
+		if !ok {
+			return filterToken{}, fmt.Errorf("unterminated string literal")
+		}
+		if r == delim {
+			text := string(l.src[start:l.pos])
+			l.pos++
+			return filterToken{kind: tokString, text: text}, nil
+		}
+		l.pos++
+	}
+}
+
+func (l *filterLexer) lexNumber() filterToken {
+	start := l.pos
+	for {
+		r, ok := l.peek()
+		if !ok || !unicode.IsDigit(r) {
+			break
+		}
+		l.pos++
+	}
+	return filterToken{kind: tokNumber, text: string(l.src[start:l.pos])}
+}
+
+func (l *filterLexer) lexIdent() filterToken {
+	start := l.pos
+	for {
+		r, ok := l.peek()
+		if !ok || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			break
+		}
+		l.pos++
+	}
+	return filterToken{kind: tokIdent, text: string(l.src[start:l.pos])}
+}
+
+// weightFilterParser is a small recursive-descent parser for expressions
+// like `>=50 && <=100`, `==10 || >=80`, `!(<20)`, or
+// `weight>=50 && name=~"US"`.
+type weightFilterParser struct {
+	lexer *filterLexer
+	tok   filterToken
+}
+
+func newWeightFilterParser(expr string) (*weightFilterParser, error) {
+	p := &weightFilterParser{lexer: newFilterLexer(expr)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *weightFilterParser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *weightFilterParser) parse() (weightPredicate, error) {
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected trailing token %q", p.tok.text)
+	}
+	return pred, nil
+}
+
+func (p *weightFilterParser) parseOr() (weightPredicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(proxy weightFilterTarget) bool { return l(proxy) || r(proxy) }
+	}
+	return left, nil
+}
+
+func (p *weightFilterParser) parseAnd() (weightPredicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(proxy weightFilterTarget) bool { return l(proxy) && r(proxy) }
+	}
+	return left, nil
+}
+
+func (p *weightFilterParser) parseUnary() (weightPredicate, error) {
+	if p.tok.kind == tokNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(proxy weightFilterTarget) bool { return !inner(proxy) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *weightFilterParser) parsePrimary() (weightPredicate, error) {
+	switch p.tok.kind {
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')', got %q", p.tok.text)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return pred, nil
+	case tokIdent:
+		switch strings.ToLower(p.tok.text) {
+		case "name":
+			return p.parseNameMatch()
+		case "weight":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			return p.parseWeightComparison()
+		default:
+			return nil, fmt.Errorf("unknown identifier %q", p.tok.text)
+		}
+	case tokComparator:
+		return p.parseWeightComparison()
+	default:
+		return nil, fmt.Errorf("unexpected token %q", p.tok.text)
+	}
+}
+
+func (p *weightFilterParser) parseNameMatch() (weightPredicate, error) {
+	if err := p.advance(); err != nil { // consume "name"
+		return nil, err
+	}
+	if p.tok.kind != tokMatch {
+		return nil, fmt.Errorf("expected '=~' after 'name', got %q", p.tok.text)
+	}
+	if err := p.advance(); err != nil { // consume "=~"
+		return nil, err
+	}
+	if p.tok.kind != tokString {
+		return nil, fmt.Errorf("expected a quoted or /regex/ pattern after '=~'")
+	}
+	re, err := regexp2.Compile(p.tok.text, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name pattern %q: %w", p.tok.text, err)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return func(proxy weightFilterTarget) bool {
+		mat, _ := re.FindStringMatch(proxy.Name())
+		return mat != nil
+	}, nil
+}
+
+func (p *weightFilterParser) parseWeightComparison() (weightPredicate, error) {
+	if p.tok.kind != tokComparator {
+		return nil, fmt.Errorf("expected a comparator (==, !=, >=, <=, >, <), got %q", p.tok.text)
+	}
+	op := p.tok.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokNumber {
+		return nil, fmt.Errorf("expected an integer after %q, got %q", op, p.tok.text)
+	}
+	val, err := strconv.Atoi(p.tok.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight literal %q: %w", p.tok.text, err)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "==":
+		return func(proxy weightFilterTarget) bool { return proxy.Weight() == val }, nil
+	case "!=":
+		return func(proxy weightFilterTarget) bool { return proxy.Weight() != val }, nil
+	case ">=":
+		return func(proxy weightFilterTarget) bool { return proxy.Weight() >= val }, nil
+	case "<=":
+		return func(proxy weightFilterTarget) bool { return proxy.Weight() <= val }, nil
+	case ">":
+		return func(proxy weightFilterTarget) bool { return proxy.Weight() > val }, nil
+	case "<":
+		return func(proxy weightFilterTarget) bool { return proxy.Weight() < val }, nil
+	default:
+		return nil, fmt.Errorf("unsupported comparator %q", op)
+	}
+}
+
+// compileWeightFilter parses a weightFilter expression into a reusable
+// predicate. An empty expression matches every proxy.
+func compileWeightFilter(expr string) (weightPredicate, error) {
+	if expr == "" {
+		return func(weightFilterTarget) bool { return true }, nil
+	}
+	parser, err := newWeightFilterParser(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight filter %q: %w", expr, err)
+	}
+	pred, err := parser.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight filter %q: %w", expr, err)
+	}
+	return pred, nil
+}
+
+// filterProxies keeps only the proxies matching pred, preserving order.
+func filterProxies(proxies []C.Proxy, pred weightPredicate) []C.Proxy {
+	if pred == nil {
+		return proxies
+	}
+	var filtered []C.Proxy
+	for _, p := range proxies {
+		if pred(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,63 @@
+package outboundgroup
+
+import "testing"
+
+type fakeWeightTarget struct {
+	name   string
+	weight int
+}
+
+func (f fakeWeightTarget) Name() string { return f.name }
+func (f fakeWeightTarget) Weight() int  { return f.weight }
+
+func TestCompileWeightFilter(t *testing.T) {
+	cases := []struct {
+		name   string
+		expr   string
+		target fakeWeightTarget
+		want   bool
+	}{
+		{"range-match", ">=50 && <=100", fakeWeightTarget{weight: 80}, true},
+		{"range-below", ">=50 && <=100", fakeWeightTarget{weight: 10}, false},
+		{"or-match-low", "==10 || >=80", fakeWeightTarget{weight: 10}, true},
+		{"or-match-high", "==10 || >=80", fakeWeightTarget{weight: 90}, true},
+		{"or-no-match", "==10 || >=80", fakeWeightTarget{weight: 50}, false},
+		{"negated-range-included", "!(<20)", fakeWeightTarget{weight: 20}, true},
+		{"negated-range-excluded", "!(<20)", fakeWeightTarget{weight: 5}, false},
+		{"strict-greater-match", "weight>50", fakeWeightTarget{weight: 51}, true},
+		{"strict-greater-boundary-excluded", "weight>50", fakeWeightTarget{weight: 50}, false},
+		{"strict-less-match", "weight<50", fakeWeightTarget{weight: 49}, true},
+		{"strict-less-boundary-excluded", "weight<50", fakeWeightTarget{weight: 50}, false},
+		{"weight-and-name-match", `weight>=50 && name=~"US"`, fakeWeightTarget{name: "US-1", weight: 60}, true},
+		{"weight-and-name-wrong-weight", `weight>=50 && name=~"US"`, fakeWeightTarget{name: "US-1", weight: 10}, false},
+		{"weight-and-name-wrong-name", `weight>=50 && name=~"US"`, fakeWeightTarget{name: "HK-1", weight: 60}, false},
+		{"empty-matches-everything", "", fakeWeightTarget{weight: -1}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pred, err := compileWeightFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("compileWeightFilter(%q) returned error: %v", tc.expr, err)
+			}
+			if got := pred(tc.target); got != tc.want {
+				t.Fatalf("compileWeightFilter(%q) on %+v = %v, want %v", tc.expr, tc.target, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompileWeightFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		">=",
+		"&&",
+		"(>=50",
+		"weight>=abc",
+		`name=~`,
+		"50>=10", // a number isn't a valid start of an expression
+	} {
+		if _, err := compileWeightFilter(expr); err == nil {
+			t.Errorf("compileWeightFilter(%q) expected an error, got nil", expr)
+		}
+	}
+}